@@ -0,0 +1,91 @@
+package stream
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+// A ConcatOperator iterates over each of its streams in order and forwards every
+// value it encounters to the next operator in the pipeline.
+type ConcatOperator struct {
+	BaseOperator
+	Streams []*Stream
+}
+
+// Concat creates an operator that iterates over each of the given streams, in order,
+// and forwards every value it encounters downstream.
+func Concat(s ...*Stream) *ConcatOperator {
+	return &ConcatOperator{Streams: s}
+}
+
+// Iterate implements the Operator interface.
+func (it *ConcatOperator) Iterate(in *expr.Environment, fn func(out *expr.Environment) error) error {
+	for _, s := range it.Streams {
+		if err := s.Iterate(fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (it *ConcatOperator) String() string {
+	return fmt.Sprintf("concat(%s)", joinStreams(it.Streams))
+}
+
+// A UnionOperator iterates over each of its streams in order and forwards every
+// value it encounters downstream, skipping values it has already forwarded.
+type UnionOperator struct {
+	BaseOperator
+	Streams []*Stream
+}
+
+// Union creates an operator that iterates over each of the given streams, in order,
+// and forwards every value it encounters downstream, deduplicating rows by hashing
+// their current value.
+func Union(s ...*Stream) *UnionOperator {
+	return &UnionOperator{Streams: s}
+}
+
+// Iterate implements the Operator interface.
+func (it *UnionOperator) Iterate(in *expr.Environment, fn func(out *expr.Environment) error) error {
+	seen := make(map[string]struct{})
+
+	for _, s := range it.Streams {
+		err := s.Iterate(func(out *expr.Environment) error {
+			v, ok := out.GetCurrentValue()
+			if !ok {
+				return fn(out)
+			}
+
+			h := document.ValueToString(v)
+			if _, ok := seen[h]; ok {
+				return nil
+			}
+			seen[h] = struct{}{}
+
+			return fn(out)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (it *UnionOperator) String() string {
+	return fmt.Sprintf("union(%s)", joinStreams(it.Streams))
+}
+
+func joinStreams(streams []*Stream) string {
+	ss := make([]string, len(streams))
+	for i, s := range streams {
+		ss[i] = s.String()
+	}
+
+	return strings.Join(ss, ", ")
+}
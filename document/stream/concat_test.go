@@ -0,0 +1,49 @@
+package stream_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/document/stream"
+	"github.com/genjidb/genji/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcatKeepsDuplicates(t *testing.T) {
+	s := stream.New(stream.Concat(
+		stream.New(values(types.NewIntegerValue(1), types.NewIntegerValue(2))),
+		stream.New(values(types.NewIntegerValue(2), types.NewIntegerValue(3))),
+	))
+
+	got, err := collect(s)
+	require.NoError(t, err)
+	require.Equal(t, []types.Value{
+		types.NewIntegerValue(1),
+		types.NewIntegerValue(2),
+		types.NewIntegerValue(2),
+		types.NewIntegerValue(3),
+	}, got)
+}
+
+func TestUnionDeduplicatesAcrossStreams(t *testing.T) {
+	s := stream.New(stream.Union(
+		stream.New(values(types.NewIntegerValue(1), types.NewIntegerValue(2))),
+		stream.New(values(types.NewIntegerValue(2), types.NewIntegerValue(3))),
+	))
+
+	got, err := collect(s)
+	require.NoError(t, err)
+	require.Equal(t, []types.Value{
+		types.NewIntegerValue(1),
+		types.NewIntegerValue(2),
+		types.NewIntegerValue(3),
+	}, got)
+}
+
+func TestDiscardDropsEverything(t *testing.T) {
+	s := stream.New(values(types.NewIntegerValue(1), types.NewIntegerValue(2)))
+	s = s.Pipe(stream.Discard())
+
+	got, err := collect(s)
+	require.NoError(t, err)
+	require.Len(t, got, 0)
+}
@@ -0,0 +1,28 @@
+package stream
+
+import (
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+// A DiscardOperator consumes every value of the stream and drops it.
+type DiscardOperator struct {
+	BaseOperator
+}
+
+// Discard creates an operator that consumes and drops every value it receives.
+// It is useful as a terminal operator for pipelines whose side effects, rather
+// than their output, are what matters, such as a DELETE with no RETURNING clause.
+func Discard() *DiscardOperator {
+	return &DiscardOperator{}
+}
+
+// Iterate implements the Operator interface.
+func (op *DiscardOperator) Iterate(in *expr.Environment, fn func(out *expr.Environment) error) error {
+	return op.IteratePrev(in, func(out *expr.Environment) error {
+		return nil
+	})
+}
+
+func (op *DiscardOperator) String() string {
+	return "discard()"
+}
@@ -0,0 +1,64 @@
+package stream
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/genjidb/genji/sql/query/expr"
+	"github.com/genjidb/genji/types"
+)
+
+// ErrInvalidResult is returned when an expression evaluated by an EmitOperator
+// doesn't evaluate to a document.
+var ErrInvalidResult = errors.New("expression must evaluate to a document")
+
+// An EmitOperator evaluates a list of expressions and pushes their result downstream.
+// It acts as a source: it ignores the value of its input and only uses it to
+// evaluate its expressions and as the outer environment of the values it emits.
+type EmitOperator struct {
+	BaseOperator
+	Exprs []expr.Expr
+}
+
+// Emit creates an operator that evaluates each of exprs against the incoming
+// environment and pushes the result downstream. Each expression must evaluate to
+// a document, otherwise ErrInvalidResult is returned. This is used to compile
+// VALUES clauses and other inline row constructors into a stream.
+func Emit(exprs ...expr.Expr) *EmitOperator {
+	return &EmitOperator{Exprs: exprs}
+}
+
+// Iterate implements the Operator interface.
+func (op *EmitOperator) Iterate(in *expr.Environment, fn func(out *expr.Environment) error) error {
+	var newEnv expr.Environment
+	newEnv.Outer = in
+
+	for _, e := range op.Exprs {
+		v, err := e.Eval(in)
+		if err != nil {
+			return err
+		}
+
+		if v.Type != types.DocumentValue {
+			return ErrInvalidResult
+		}
+
+		newEnv.SetCurrentValue(v)
+
+		if err := fn(&newEnv); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (op *EmitOperator) String() string {
+	exprs := make([]string, len(op.Exprs))
+	for i, e := range op.Exprs {
+		exprs[i] = e.String()
+	}
+
+	return fmt.Sprintf("emit(%s)", strings.Join(exprs, ", "))
+}
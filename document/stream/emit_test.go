@@ -0,0 +1,28 @@
+package stream_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/document/stream"
+	"github.com/genjidb/genji/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmit(t *testing.T) {
+	doc1 := types.NewDocumentValue(document.NewFieldBuffer().Add("a", types.NewIntegerValue(1)))
+	doc2 := types.NewDocumentValue(document.NewFieldBuffer().Add("a", types.NewIntegerValue(2)))
+
+	s := stream.New(stream.Emit(lit(doc1), lit(doc2)))
+
+	got, err := collect(s)
+	require.NoError(t, err)
+	require.Equal(t, []types.Value{doc1, doc2}, got)
+}
+
+func TestEmitInvalidResult(t *testing.T) {
+	s := stream.New(stream.Emit(lit(types.NewIntegerValue(1))))
+
+	_, err := collect(s)
+	require.ErrorIs(t, err, stream.ErrInvalidResult)
+}
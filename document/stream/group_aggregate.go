@@ -0,0 +1,133 @@
+package stream
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query/expr"
+	"github.com/genjidb/genji/types"
+)
+
+// A GroupAggregateOperator groups rows by E and applies one aggregator per group,
+// built from Builders. It assumes the incoming rows are already sorted by E: the
+// planner is expected to have inserted a sort operator, or to rely on an index,
+// upstream of this operator.
+type GroupAggregateOperator struct {
+	BaseOperator
+
+	E        expr.Expr
+	Builders []expr.AggregatorBuilder
+}
+
+// GroupAggregate groups the incoming rows, which must already be sorted by groupExpr,
+// and applies builders to each group. It emits one row per group, made of the group
+// key plus the result of each aggregator.
+// If groupExpr is nil, the whole stream is treated as a single group and exactly one
+// row is emitted.
+func GroupAggregate(groupExpr expr.Expr, builders ...expr.AggregatorBuilder) *GroupAggregateOperator {
+	return &GroupAggregateOperator{E: groupExpr, Builders: builders}
+}
+
+// Iterate implements the Operator interface.
+func (op *GroupAggregateOperator) Iterate(in *expr.Environment, fn func(out *expr.Environment) error) error {
+	aggregators := op.newAggregators()
+
+	var curGroup types.Value
+	var hasGroup bool
+	lastRow := in
+
+	flush := func() error {
+		fb := document.NewFieldBuffer()
+
+		if op.E != nil {
+			fb.Add(op.E.String(), curGroup)
+		}
+
+		for i, b := range op.Builders {
+			v, err := aggregators[i].Eval(lastRow)
+			if err != nil {
+				return err
+			}
+
+			fb.Add(b.String(), v)
+		}
+
+		var newEnv expr.Environment
+		newEnv.SetCurrentValue(types.NewDocumentValue(fb))
+		newEnv.Outer = lastRow
+		return fn(&newEnv)
+	}
+
+	err := op.IteratePrev(in, func(out *expr.Environment) error {
+		if op.E == nil {
+			lastRow = out
+			return aggregate(aggregators, out)
+		}
+
+		v, err := op.E.Eval(out)
+		if err != nil {
+			return err
+		}
+
+		if hasGroup && !sameGroup(curGroup, v) {
+			if err := flush(); err != nil {
+				return err
+			}
+
+			aggregators = op.newAggregators()
+		}
+
+		curGroup = v
+		hasGroup = true
+		lastRow = out
+
+		return aggregate(aggregators, out)
+	})
+	if err != nil {
+		return err
+	}
+
+	if op.E == nil || hasGroup {
+		return flush()
+	}
+
+	return nil
+}
+
+func (op *GroupAggregateOperator) newAggregators() []expr.Aggregator {
+	aggregators := make([]expr.Aggregator, len(op.Builders))
+	for i, b := range op.Builders {
+		aggregators[i] = b.Aggregator()
+	}
+
+	return aggregators
+}
+
+func aggregate(aggregators []expr.Aggregator, env *expr.Environment) error {
+	for _, a := range aggregators {
+		if err := a.Aggregate(env); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sameGroup(a, b types.Value) bool {
+	return document.ValueToString(a) == document.ValueToString(b)
+}
+
+func (op *GroupAggregateOperator) String() string {
+	var parts []string
+
+	if op.E != nil {
+		parts = append(parts, op.E.String())
+	}
+
+	for _, b := range op.Builders {
+		parts = append(parts, b.String())
+	}
+
+	return fmt.Sprintf("groupAggregate(%s)", strings.Join(parts, ", "))
+}
@@ -0,0 +1,59 @@
+package stream_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/document/stream"
+	"github.com/genjidb/genji/sql/query/expr"
+	"github.com/genjidb/genji/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupAggregateNilGroupExpr(t *testing.T) {
+	s := stream.New(values(
+		types.NewIntegerValue(1),
+		types.NewIntegerValue(2),
+		types.NewIntegerValue(3),
+	))
+	s = s.Pipe(stream.GroupAggregate(nil, expr.Count(nil)))
+
+	got, err := collect(s)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	v, err := got[0].V.(*document.FieldBuffer).GetByField("COUNT(*)")
+	require.NoError(t, err)
+	require.Equal(t, types.NewIntegerValue(3), v)
+}
+
+func TestGroupAggregateEmptyInput(t *testing.T) {
+	s := stream.New(values())
+	s = s.Pipe(stream.GroupAggregate(field(), expr.Count(field())))
+
+	got, err := collect(s)
+	require.NoError(t, err)
+	require.Len(t, got, 0)
+}
+
+func TestGroupAggregateEmptyInputNilGroupExpr(t *testing.T) {
+	s := stream.New(values())
+	s = s.Pipe(stream.GroupAggregate(nil, expr.Count(field())))
+
+	got, err := collect(s)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+}
+
+func TestGroupAggregateMultipleGroups(t *testing.T) {
+	s := stream.New(values(
+		types.NewIntegerValue(1),
+		types.NewIntegerValue(1),
+		types.NewIntegerValue(2),
+	))
+	s = s.Pipe(stream.GroupAggregate(field(), expr.Count(nil)))
+
+	got, err := collect(s)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+}
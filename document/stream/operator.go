@@ -12,24 +12,66 @@ const (
 	accEnvKey   = "_acc"
 )
 
-// An Operator is used to modify a stream.
-// It takes an environment containing the current value as well as any other metadata
-// created by other operatorsand returns a new environment which will be passed to the next operator.
-// If it returns a nil environment, the env will be ignored.
-// If it returns an error, the stream will be interrupted and that error will bubble up
-// and returned by this function, unless that error is ErrStreamClosed, in which case
-// the Iterate method will stop the iteration and return nil.
-// Stream operators can be reused, and thus, any state or side effect should be kept within the Op closure
-// unless the nature of the operator prevents that.
+// An Operator is a node of a stream pipeline. It pulls environments from Prev by
+// calling Iterate on the previous operator in the chain, transforms or filters them,
+// and pushes the result downstream by calling fn.
+// If it returns an error, the stream will be interrupted and that error will bubble up,
+// unless that error is ErrStreamClosed, in which case the Iterate method will stop the
+// iteration and return nil.
+// Operators are linked together in a doubly-linked list so that an operator, or the
+// planner, can walk back and forth the pipeline to inspect or rearrange neighbors.
+// Stream operators can be reused, and thus, any state or side effect should be kept
+// local to Iterate unless the nature of the operator prevents that.
 type Operator interface {
-	Op() (OperatorFunc, error)
+	Iterate(in *expr.Environment, fn func(out *expr.Environment) error) error
+	GetPrev() Operator
+	SetPrev(Operator)
+	GetNext() Operator
+	SetNext(Operator)
+	String() string
 }
 
-// An OperatorFunc is the function that will receive each value of the stream.
-type OperatorFunc func(env *expr.Environment) (*expr.Environment, error)
+// A BaseOperator can be embedded by an operator to avoid reimplementing the
+// GetPrev/SetPrev/GetNext/SetNext methods of the Operator interface.
+type BaseOperator struct {
+	Prev Operator
+	Next Operator
+}
+
+// GetPrev implements the Operator interface.
+func (op *BaseOperator) GetPrev() Operator {
+	return op.Prev
+}
+
+// SetPrev implements the Operator interface.
+func (op *BaseOperator) SetPrev(o Operator) {
+	op.Prev = o
+}
+
+// GetNext implements the Operator interface.
+func (op *BaseOperator) GetNext() Operator {
+	return op.Next
+}
+
+// SetNext implements the Operator interface.
+func (op *BaseOperator) SetNext(o Operator) {
+	op.Next = o
+}
+
+// IteratePrev pulls values from Prev, or, if the operator has no Prev, treats in
+// as the single incoming value. This is the entry point used by relay operators
+// that don't produce values on their own.
+func (op *BaseOperator) IteratePrev(in *expr.Environment, fn func(out *expr.Environment) error) error {
+	if op.Prev == nil {
+		return fn(in)
+	}
+
+	return op.Prev.Iterate(in, fn)
+}
 
 // A MapOperator applies an expression on each value of the stream and returns a new value.
 type MapOperator struct {
+	BaseOperator
 	E expr.Expr
 }
 
@@ -38,20 +80,20 @@ func Map(e expr.Expr) *MapOperator {
 	return &MapOperator{E: e}
 }
 
-// Op implements the Operator interface.
-func (m *MapOperator) Op() (OperatorFunc, error) {
+// Iterate implements the Operator interface.
+func (m *MapOperator) Iterate(in *expr.Environment, fn func(out *expr.Environment) error) error {
 	var newEnv expr.Environment
 
-	return func(env *expr.Environment) (*expr.Environment, error) {
-		v, err := m.E.Eval(env)
+	return m.IteratePrev(in, func(out *expr.Environment) error {
+		v, err := m.E.Eval(out)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		newEnv.SetCurrentValue(v)
-		newEnv.Outer = env
-		return &newEnv, nil
-	}, nil
+		newEnv.Outer = out
+		return fn(&newEnv)
+	})
 }
 
 func (m *MapOperator) String() string {
@@ -60,6 +102,7 @@ func (m *MapOperator) String() string {
 
 // A FilterOperator filters values based on a given expression.
 type FilterOperator struct {
+	BaseOperator
 	E expr.Expr
 }
 
@@ -68,25 +111,25 @@ func Filter(e expr.Expr) *FilterOperator {
 	return &FilterOperator{E: e}
 }
 
-// Op implements the Operator interface.
-func (m *FilterOperator) Op() (OperatorFunc, error) {
-	return func(env *expr.Environment) (*expr.Environment, error) {
-		v, err := m.E.Eval(env)
+// Iterate implements the Operator interface.
+func (m *FilterOperator) Iterate(in *expr.Environment, fn func(out *expr.Environment) error) error {
+	return m.IteratePrev(in, func(out *expr.Environment) error {
+		v, err := m.E.Eval(out)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		ok, err := v.IsTruthy()
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		if !ok {
-			return nil, nil
+			return nil
 		}
 
-		return env, nil
-	}, nil
+		return fn(out)
+	})
 }
 
 func (m *FilterOperator) String() string {
@@ -95,6 +138,7 @@ func (m *FilterOperator) String() string {
 
 // A TakeOperator closes the stream after a certain number of values.
 type TakeOperator struct {
+	BaseOperator
 	E expr.Expr
 }
 
@@ -104,29 +148,30 @@ func Take(n expr.Expr) *TakeOperator {
 	return &TakeOperator{E: n}
 }
 
-// Op implements the Operator interface.
-func (m *TakeOperator) Op() (OperatorFunc, error) {
-	var n, count int64
-	v, err := m.E.Eval(&expr.Environment{})
+// Iterate implements the Operator interface.
+func (m *TakeOperator) Iterate(in *expr.Environment, fn func(out *expr.Environment) error) error {
+	v, err := m.E.Eval(in)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if v.Type != document.IntegerValue {
 		v, err = v.CastAsInteger()
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
-	n = v.V.(int64)
+	n := v.V.(int64)
 
-	return func(env *expr.Environment) (*expr.Environment, error) {
-		if count < n {
-			count++
-			return env, nil
+	var count int64
+
+	return m.IteratePrev(in, func(out *expr.Environment) error {
+		if count >= n {
+			return ErrStreamClosed
 		}
 
-		return nil, ErrStreamClosed
-	}, nil
+		count++
+		return fn(out)
+	})
 }
 
 func (m *TakeOperator) String() string {
@@ -135,6 +180,7 @@ func (m *TakeOperator) String() string {
 
 // A SkipOperator skips the n first values of the stream.
 type SkipOperator struct {
+	BaseOperator
 	E expr.Expr
 }
 
@@ -144,29 +190,30 @@ func Skip(n expr.Expr) *SkipOperator {
 	return &SkipOperator{E: n}
 }
 
-// Op implements the Operator interface.
-func (m *SkipOperator) Op() (OperatorFunc, error) {
-	var n, skipped int64
-	v, err := m.E.Eval(&expr.Environment{})
+// Iterate implements the Operator interface.
+func (m *SkipOperator) Iterate(in *expr.Environment, fn func(out *expr.Environment) error) error {
+	v, err := m.E.Eval(in)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if v.Type != document.IntegerValue {
 		v, err = v.CastAsInteger()
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
-	n = v.V.(int64)
+	n := v.V.(int64)
+
+	var skipped int64
 
-	return func(env *expr.Environment) (*expr.Environment, error) {
+	return m.IteratePrev(in, func(out *expr.Environment) error {
 		if skipped < n {
 			skipped++
-			return nil, nil
+			return nil
 		}
 
-		return env, nil
-	}, nil
+		return fn(out)
+	})
 }
 
 func (m *SkipOperator) String() string {
@@ -176,6 +223,7 @@ func (m *SkipOperator) String() string {
 // A GroupByOperator applies an expression on each value of the stream and stores the result in the _group
 // variable in the output stream.
 type GroupByOperator struct {
+	BaseOperator
 	E expr.Expr
 }
 
@@ -185,38 +233,37 @@ func GroupBy(e expr.Expr) *GroupByOperator {
 	return &GroupByOperator{E: e}
 }
 
-// Op implements the Operator interface.
-func (op *GroupByOperator) Op() (OperatorFunc, error) {
+// Iterate implements the Operator interface.
+func (op *GroupByOperator) Iterate(in *expr.Environment, fn func(out *expr.Environment) error) error {
 	var newEnv expr.Environment
 
-	return func(env *expr.Environment) (*expr.Environment, error) {
-		v, err := op.E.Eval(env)
+	return op.IteratePrev(in, func(out *expr.Environment) error {
+		v, err := op.E.Eval(out)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		newEnv.Set(groupEnvKey, v)
-		newEnv.Outer = env
-		return &newEnv, nil
-	}, nil
+		newEnv.Outer = out
+		return fn(&newEnv)
+	})
 }
 
 func (op *GroupByOperator) String() string {
 	return fmt.Sprintf("groupBy(%s)", op.E)
 }
 
-// A ReduceOperator consumes the given stream and outputs one value per group.
-// It reads the _group variable from the environment to determine with group
+// A ReduceOperator consumes the entire stream and outputs one value per group.
+// It reads the _group variable from the environment to determine which group
 // to assign each value. If no _group variable is available, it will assume all
 // values are part of the same group and reduce them into one value.
-// To reduce incoming values, reduce
 type ReduceOperator struct {
+	BaseOperator
 	Seed, Accumulator expr.Expr
-	Stream            Stream
 }
 
 // Reduce consumes the incoming stream and outputs one value per group.
-// It reads the _group variable from the environment to determine whitch group
+// It reads the _group variable from the environment to determine which group
 // to assign each value. If no _group variable is available, it will assume all
 // values are part of the same group and reduce them into one value.
 // The seed is used to determine the initial value of the reduction. The initial value
@@ -227,28 +274,21 @@ func Reduce(seed, accumulator expr.Expr) *ReduceOperator {
 	return &ReduceOperator{Seed: seed, Accumulator: accumulator}
 }
 
-// Pipe stores s in the operator and return a new Stream with the reduce operator appended. It implements the Piper interface.
-func (op *ReduceOperator) Pipe(s Stream) Stream {
-	op.Stream = s
-
-	return Stream{
-		it: s,
-		op: op,
-	}
-}
-
-// Op implements the Operator interface.
-func (op *ReduceOperator) Op() (OperatorFunc, error) {
+// Iterate implements the Operator interface. It pulls the entire upstream of the
+// operator before emitting the reduced value, since the result can only be known
+// once the last value of the stream has been seen.
+func (op *ReduceOperator) Iterate(in *expr.Environment, fn func(out *expr.Environment) error) error {
 	var newEnv expr.Environment
 
-	seed, err := op.Seed.Eval(&newEnv)
+	seed, err := op.Seed.Eval(in)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	newEnv.Set(accEnvKey, seed)
-	err = op.Stream.Iterate(func(env *expr.Environment) error {
-		newEnv.Outer = env
+
+	err = op.IteratePrev(in, func(out *expr.Environment) error {
+		newEnv.Outer = out
 		v, err := op.Accumulator.Eval(&newEnv)
 		if err != nil {
 			return err
@@ -258,17 +298,15 @@ func (op *ReduceOperator) Op() (OperatorFunc, error) {
 		return nil
 	})
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return func(env *expr.Environment) (*expr.Environment, error) {
-		v, _ := newEnv.Get(document.Path{document.PathFragment{FieldName: accEnvKey}})
-		newEnv.SetCurrentValue(v)
-		newEnv.Outer = env
-		return &newEnv, nil
-	}, nil
+	v, _ := newEnv.Get(document.Path{document.PathFragment{FieldName: accEnvKey}})
+	newEnv.SetCurrentValue(v)
+	newEnv.Outer = in
+	return fn(&newEnv)
 }
 
 func (op *ReduceOperator) String() string {
 	return fmt.Sprintf("reduce(%s, %s)", op.Seed, op.Accumulator)
-}
\ No newline at end of file
+}
@@ -0,0 +1,41 @@
+package stream_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/document/stream"
+	"github.com/genjidb/genji/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTakeClosesUpstreamChain(t *testing.T) {
+	s := stream.New(values(
+		types.NewIntegerValue(1),
+		types.NewIntegerValue(2),
+		types.NewIntegerValue(3),
+		types.NewIntegerValue(4),
+	))
+	s = s.Pipe(stream.Map(field()))
+	s = s.Pipe(stream.Filter(field()))
+	s = s.Pipe(stream.Take(lit(types.NewIntegerValue(2))))
+
+	got, err := collect(s)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+}
+
+func TestSkip(t *testing.T) {
+	s := stream.New(values(
+		types.NewIntegerValue(1),
+		types.NewIntegerValue(2),
+		types.NewIntegerValue(3),
+	))
+	s = s.Pipe(stream.Skip(lit(types.NewIntegerValue(1))))
+
+	got, err := collect(s)
+	require.NoError(t, err)
+	require.Equal(t, []types.Value{
+		types.NewIntegerValue(2),
+		types.NewIntegerValue(3),
+	}, got)
+}
@@ -0,0 +1,73 @@
+package stream
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+// ErrStreamClosed is used to indicate that a stream must be closed.
+var ErrStreamClosed = errors.New("stream closed")
+
+// A Stream is a pipeline of operators that manipulate data one environment at a time.
+// Operators are linked together as a doubly-linked list: Op points to the last operator
+// of the pipeline, which can walk back to the first one through its Prev pointers.
+type Stream struct {
+	Op Operator
+}
+
+// New creates a new Stream using the given operator as the first operator of the pipeline.
+func New(op Operator) *Stream {
+	return &Stream{Op: op}
+}
+
+// Pipe appends op to the stream and returns a new stream pointing to it.
+func (s *Stream) Pipe(op Operator) *Stream {
+	if s.Op != nil {
+		op.SetPrev(s.Op)
+		s.Op.SetNext(op)
+	}
+
+	return &Stream{Op: op}
+}
+
+// Iterate runs the stream until the end or until fn returns an error.
+// If the error is ErrStreamClosed, Iterate stops the iteration and returns nil.
+func (s *Stream) Iterate(fn func(out *expr.Environment) error) error {
+	if s.Op == nil {
+		return nil
+	}
+
+	var env expr.Environment
+
+	err := s.Op.Iterate(&env, fn)
+	if err != nil && err != ErrStreamClosed {
+		return err
+	}
+
+	return nil
+}
+
+// String returns a string representation of the stream by joining the string
+// representation of every operator of the pipeline with a pipe character.
+func (s *Stream) String() string {
+	if s.Op == nil {
+		return ""
+	}
+
+	var ops []Operator
+	for op := s.Op; op != nil; op = op.GetPrev() {
+		ops = append(ops, op)
+	}
+
+	var sb strings.Builder
+	for i := len(ops) - 1; i >= 0; i-- {
+		sb.WriteString(ops[i].String())
+		if i > 0 {
+			sb.WriteString(" | ")
+		}
+	}
+
+	return sb.String()
+}
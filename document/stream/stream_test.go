@@ -0,0 +1,86 @@
+package stream_test
+
+import (
+	"fmt"
+
+	"github.com/genjidb/genji/document/stream"
+	"github.com/genjidb/genji/sql/query/expr"
+	"github.com/genjidb/genji/types"
+)
+
+// valuesOperator is a bare source operator that feeds a fixed list of values
+// through the pipeline. It is only used to build test streams.
+type valuesOperator struct {
+	stream.BaseOperator
+	Values []types.Value
+}
+
+func values(vs ...types.Value) *valuesOperator {
+	return &valuesOperator{Values: vs}
+}
+
+// Iterate implements the stream.Operator interface.
+func (op *valuesOperator) Iterate(in *expr.Environment, fn func(out *expr.Environment) error) error {
+	for _, v := range op.Values {
+		var newEnv expr.Environment
+		newEnv.SetCurrentValue(v)
+		newEnv.Outer = in
+
+		if err := fn(&newEnv); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (op *valuesOperator) String() string {
+	return "values()"
+}
+
+// litExpr is an expr.Expr that always evaluates to the same value, regardless
+// of the environment it is given.
+type litExpr struct {
+	Value types.Value
+}
+
+func lit(v types.Value) *litExpr {
+	return &litExpr{Value: v}
+}
+
+func (e *litExpr) Eval(env *expr.Environment) (types.Value, error) {
+	return e.Value, nil
+}
+
+func (e *litExpr) String() string {
+	return fmt.Sprintf("%v", e.Value.V)
+}
+
+// fieldExpr evaluates to the current value of env, ignoring its contents.
+type fieldExpr struct{}
+
+func field() *fieldExpr {
+	return &fieldExpr{}
+}
+
+func (e *fieldExpr) Eval(env *expr.Environment) (types.Value, error) {
+	v, _ := env.GetCurrentValue()
+	return v, nil
+}
+
+func (e *fieldExpr) String() string {
+	return "v"
+}
+
+// collect drains s and returns every current value it produced, in order.
+func collect(s *stream.Stream) ([]types.Value, error) {
+	var got []types.Value
+
+	err := s.Iterate(func(out *expr.Environment) error {
+		v, _ := out.GetCurrentValue()
+		got = append(got, v)
+		return nil
+	})
+
+	return got, err
+}
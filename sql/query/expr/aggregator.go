@@ -0,0 +1,283 @@
+package expr
+
+import (
+	"fmt"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/types"
+)
+
+// An AggregatorBuilder can create a new Aggregator. Aggregation operators
+// call Aggregator once per group so that each group gets its own, independent
+// accumulator.
+type AggregatorBuilder interface {
+	Aggregator() Aggregator
+	String() string
+}
+
+// An Aggregator folds the values it is fed through Aggregate into a single one,
+// returned by Eval.
+type Aggregator interface {
+	// Aggregate evaluates the aggregator's expression against env and folds the
+	// result into the aggregator's state.
+	Aggregate(env *Environment) error
+	// Eval returns the aggregated value.
+	Eval(env *Environment) (types.Value, error)
+}
+
+// CountBuilder is an AggregatorBuilder for the COUNT function.
+type CountBuilder struct {
+	Expr Expr
+}
+
+// Count creates an AggregatorBuilder that counts the number of non-null values
+// returned by e. If e is nil, it counts every incoming row, i.e. COUNT(*).
+func Count(e Expr) *CountBuilder {
+	return &CountBuilder{Expr: e}
+}
+
+// Aggregator implements the AggregatorBuilder interface.
+func (b *CountBuilder) Aggregator() Aggregator {
+	return &countAggregator{Expr: b.Expr}
+}
+
+func (b *CountBuilder) String() string {
+	if b.Expr == nil {
+		return "COUNT(*)"
+	}
+
+	return fmt.Sprintf("COUNT(%s)", b.Expr)
+}
+
+type countAggregator struct {
+	Expr  Expr
+	Count int64
+}
+
+func (a *countAggregator) Aggregate(env *Environment) error {
+	if a.Expr == nil {
+		a.Count++
+		return nil
+	}
+
+	v, err := a.Expr.Eval(env)
+	if err != nil {
+		return err
+	}
+
+	if v.Type != types.NullValue {
+		a.Count++
+	}
+
+	return nil
+}
+
+func (a *countAggregator) Eval(env *Environment) (types.Value, error) {
+	return types.NewIntegerValue(a.Count), nil
+}
+
+// SumBuilder is an AggregatorBuilder for the SUM function.
+type SumBuilder struct {
+	Expr Expr
+}
+
+// Sum creates an AggregatorBuilder that sums the values returned by e, ignoring nulls.
+// It evaluates to NULL if no non-null value was aggregated.
+func Sum(e Expr) *SumBuilder {
+	return &SumBuilder{Expr: e}
+}
+
+// Aggregator implements the AggregatorBuilder interface.
+func (b *SumBuilder) Aggregator() Aggregator {
+	return &sumAggregator{Expr: b.Expr}
+}
+
+func (b *SumBuilder) String() string {
+	return fmt.Sprintf("SUM(%s)", b.Expr)
+}
+
+type sumAggregator struct {
+	Expr Expr
+	Sum  float64
+	Set  bool
+}
+
+func (a *sumAggregator) Aggregate(env *Environment) error {
+	v, err := a.Expr.Eval(env)
+	if err != nil {
+		return err
+	}
+
+	if v.Type == types.NullValue {
+		return nil
+	}
+
+	v, err = v.CastAsDouble()
+	if err != nil {
+		return err
+	}
+
+	a.Sum += v.V.(float64)
+	a.Set = true
+	return nil
+}
+
+func (a *sumAggregator) Eval(env *Environment) (types.Value, error) {
+	if !a.Set {
+		return types.NewNullValue(), nil
+	}
+
+	return types.NewDoubleValue(a.Sum), nil
+}
+
+// AvgBuilder is an AggregatorBuilder for the AVG function.
+type AvgBuilder struct {
+	Expr Expr
+}
+
+// Avg creates an AggregatorBuilder that averages the values returned by e, ignoring nulls.
+// It evaluates to NULL if no non-null value was aggregated.
+func Avg(e Expr) *AvgBuilder {
+	return &AvgBuilder{Expr: e}
+}
+
+// Aggregator implements the AggregatorBuilder interface.
+func (b *AvgBuilder) Aggregator() Aggregator {
+	return &avgAggregator{Expr: b.Expr}
+}
+
+func (b *AvgBuilder) String() string {
+	return fmt.Sprintf("AVG(%s)", b.Expr)
+}
+
+type avgAggregator struct {
+	Expr  Expr
+	Sum   float64
+	Count int64
+}
+
+func (a *avgAggregator) Aggregate(env *Environment) error {
+	v, err := a.Expr.Eval(env)
+	if err != nil {
+		return err
+	}
+
+	if v.Type == types.NullValue {
+		return nil
+	}
+
+	v, err = v.CastAsDouble()
+	if err != nil {
+		return err
+	}
+
+	a.Sum += v.V.(float64)
+	a.Count++
+	return nil
+}
+
+func (a *avgAggregator) Eval(env *Environment) (types.Value, error) {
+	if a.Count == 0 {
+		return types.NewNullValue(), nil
+	}
+
+	return types.NewDoubleValue(a.Sum / float64(a.Count)), nil
+}
+
+// MinBuilder is an AggregatorBuilder for the MIN function.
+type MinBuilder struct {
+	Expr Expr
+}
+
+// Min creates an AggregatorBuilder that keeps the smallest non-null value returned by e.
+func Min(e Expr) *MinBuilder {
+	return &MinBuilder{Expr: e}
+}
+
+// Aggregator implements the AggregatorBuilder interface.
+func (b *MinBuilder) Aggregator() Aggregator {
+	return &extremumAggregator{Expr: b.Expr, keepLeft: isLess}
+}
+
+func (b *MinBuilder) String() string {
+	return fmt.Sprintf("MIN(%s)", b.Expr)
+}
+
+// MaxBuilder is an AggregatorBuilder for the MAX function.
+type MaxBuilder struct {
+	Expr Expr
+}
+
+// Max creates an AggregatorBuilder that keeps the greatest non-null value returned by e.
+func Max(e Expr) *MaxBuilder {
+	return &MaxBuilder{Expr: e}
+}
+
+// Aggregator implements the AggregatorBuilder interface.
+func (b *MaxBuilder) Aggregator() Aggregator {
+	return &extremumAggregator{Expr: b.Expr, keepLeft: isGreater}
+}
+
+func (b *MaxBuilder) String() string {
+	return fmt.Sprintf("MAX(%s)", b.Expr)
+}
+
+// extremumAggregator backs both MIN and MAX: keepLeft decides, given the current
+// extremum and a candidate, whether the current extremum should be kept; the
+// candidate replaces it only when keepLeft returns false.
+type extremumAggregator struct {
+	Expr     Expr
+	Value    types.Value
+	Set      bool
+	keepLeft func(left, right types.Value) (bool, error)
+}
+
+func (a *extremumAggregator) Aggregate(env *Environment) error {
+	v, err := a.Expr.Eval(env)
+	if err != nil {
+		return err
+	}
+
+	if v.Type == types.NullValue {
+		return nil
+	}
+
+	if !a.Set {
+		a.Value = v
+		a.Set = true
+		return nil
+	}
+
+	keep, err := a.keepLeft(a.Value, v)
+	if err != nil {
+		return err
+	}
+
+	if !keep {
+		a.Value = v
+	}
+
+	return nil
+}
+
+func (a *extremumAggregator) Eval(env *Environment) (types.Value, error) {
+	if !a.Set {
+		return types.NewNullValue(), nil
+	}
+
+	return a.Value, nil
+}
+
+// isLess reports whether left should be kept over right by MIN, i.e. whether
+// left is lesser than or equal to right. It compares values natively, the same
+// way the <= operator does, so MIN also works on text, blob and date columns,
+// not just numbers.
+func isLess(left, right types.Value) (bool, error) {
+	return document.IsLesserThanOrEqual(left, right)
+}
+
+// isGreater reports whether left should be kept over right by MAX, i.e. whether
+// left is greater than or equal to right.
+func isGreater(left, right types.Value) (bool, error) {
+	return document.IsGreaterThanOrEqual(left, right)
+}
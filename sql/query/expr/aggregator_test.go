@@ -0,0 +1,123 @@
+package expr_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/sql/query/expr"
+	"github.com/genjidb/genji/types"
+	"github.com/stretchr/testify/require"
+)
+
+// fieldExpr evaluates to the current value of the environment it is given.
+type fieldExpr struct{}
+
+func field() *fieldExpr {
+	return &fieldExpr{}
+}
+
+func (e *fieldExpr) Eval(env *expr.Environment) (types.Value, error) {
+	v, _ := env.GetCurrentValue()
+	return v, nil
+}
+
+func (e *fieldExpr) String() string {
+	return "v"
+}
+
+// aggregate feeds each of values to a fresh Aggregator built from b, one at a time,
+// and returns the result of Eval once all of them have been fed.
+func aggregate(t *testing.T, b expr.AggregatorBuilder, values []types.Value) types.Value {
+	t.Helper()
+
+	a := b.Aggregator()
+
+	for _, v := range values {
+		var env expr.Environment
+		env.SetCurrentValue(v)
+		require.NoError(t, a.Aggregate(&env))
+	}
+
+	res, err := a.Eval(&expr.Environment{})
+	require.NoError(t, err)
+	return res
+}
+
+func TestSum(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []types.Value
+		expected types.Value
+	}{
+		{"ignores nulls", []types.Value{
+			types.NewIntegerValue(1), types.NewNullValue(), types.NewIntegerValue(2), types.NewIntegerValue(3),
+		}, types.NewDoubleValue(6)},
+		{"all nulls", []types.Value{types.NewNullValue(), types.NewNullValue()}, types.NewNullValue()},
+		{"no values", nil, types.NewNullValue()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, aggregate(t, expr.Sum(field()), test.values))
+		})
+	}
+}
+
+func TestAvg(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []types.Value
+		expected types.Value
+	}{
+		{"ignores nulls", []types.Value{
+			types.NewIntegerValue(2), types.NewNullValue(), types.NewIntegerValue(4),
+		}, types.NewDoubleValue(3)},
+		{"all nulls", []types.Value{types.NewNullValue()}, types.NewNullValue()},
+		{"no values", nil, types.NewNullValue()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, aggregate(t, expr.Avg(field()), test.values))
+		})
+	}
+}
+
+func TestMin(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []types.Value
+		expected types.Value
+	}{
+		{"ignores nulls", []types.Value{
+			types.NewIntegerValue(3), types.NewNullValue(), types.NewIntegerValue(1), types.NewIntegerValue(2),
+		}, types.NewIntegerValue(1)},
+		{"all nulls", []types.Value{types.NewNullValue()}, types.NewNullValue()},
+		{"no values", nil, types.NewNullValue()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, aggregate(t, expr.Min(field()), test.values))
+		})
+	}
+}
+
+func TestMax(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []types.Value
+		expected types.Value
+	}{
+		{"ignores nulls", []types.Value{
+			types.NewIntegerValue(3), types.NewNullValue(), types.NewIntegerValue(1), types.NewIntegerValue(2),
+		}, types.NewIntegerValue(3)},
+		{"all nulls", []types.Value{types.NewNullValue()}, types.NewNullValue()},
+		{"no values", nil, types.NewNullValue()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, aggregate(t, expr.Max(field()), test.values))
+		})
+	}
+}